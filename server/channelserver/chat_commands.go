@@ -0,0 +1,263 @@
+package channelserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// chatCommandPrefix introduces an in-chat staff command, e.g. "/kick Bob".
+const chatCommandPrefix = "/"
+
+// ChatCommand handles the arguments following a command name (the name
+// itself is not included in args).
+type ChatCommand func(s *Server, session *Session, args []string)
+
+type chatCommandEntry struct {
+	handler ChatCommand
+	gmOnly  bool
+	help    string
+}
+
+// chatCommands is the process-wide registry every Server dispatches
+// against; commands don't carry per-server state, so one registry is
+// shared the same way binpacket/mhfpacket opcode tables are.
+var chatCommands = map[string]chatCommandEntry{}
+
+func registerChatCommand(name string, gmOnly bool, help string, handler ChatCommand) {
+	chatCommands[name] = chatCommandEntry{handler: handler, gmOnly: gmOnly, help: help}
+}
+
+func init() {
+	registerChatCommand("kick", true, "/kick <name> - disconnects a character", cmdKick)
+	registerChatCommand("mute", true, "/mute <name> <duration> - silences a character's chat, e.g. /mute Bob 10m", cmdMute)
+	registerChatCommand("broadcast", true, "/broadcast <message> - sends message to every channel", cmdBroadcast)
+	registerChatCommand("who", false, "/who - lists characters connected to this channel", cmdWho)
+	registerChatCommand("find", false, "/find <name> - reports which channel a character is connected to", cmdFind)
+	registerChatCommand("ravi", false, "/ravi status - reports the world boss's register/state", cmdRavi)
+	registerChatCommand("stats", true, "/stats - reports session counts per channel", cmdStats)
+	registerChatCommand("help", false, "/help - lists available commands", cmdHelp)
+}
+
+// HandleChatMessage intercepts chatCommandPrefix-prefixed chat messages as
+// staff commands, and enforces /mute by silently swallowing chat from a
+// muted character. It returns true when it has fully handled message
+// (command dispatched, or sender muted) and the caller should not also
+// broadcast it.
+//
+// Nothing calls this yet. It is not wired into broadcastChatMessage:
+// that function is the outbound path server-originated text (system
+// announcements, /broadcast, bridge fan-out) goes through, not where a
+// player's own chat line first arrives. The inbound side — whatever
+// handles the client's chat packet (MsgSysCastedBinary with
+// BinaryMessageTypeChat) before it ever reaches broadcastChatMessage —
+// isn't part of this snapshot; this repo has no incoming-packet handler
+// file for it to call from. The real call site is there: decode the
+// player's message, call HandleChatMessage, and only fall through to
+// broadcasting it once this returns false.
+func (s *Server) HandleChatMessage(session *Session, message string) bool {
+	if s.isMuted(session.charID) {
+		return true
+	}
+
+	if !strings.HasPrefix(message, chatCommandPrefix) {
+		return false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(message, chatCommandPrefix))
+	if len(fields) == 0 {
+		return false
+	}
+
+	cmd, ok := chatCommands[strings.ToLower(fields[0])]
+	if !ok {
+		return false
+	}
+
+	if cmd.gmOnly {
+		isStaff, err := s.IsChatStaff(session.charID)
+		if err != nil {
+			s.logger.Error("failed to check chat command permissions", zap.Error(err), zap.Uint32("charID", session.charID))
+			return true
+		}
+		if !isStaff {
+			s.WhisperChatMessage(session, s.name, "you do not have permission to use that command")
+			return true
+		}
+	}
+
+	cmd.handler(s, session, fields[1:])
+	return true
+}
+
+// IsChatStaff reports whether charID may run a gmOnly command: a guild
+// leader/sub-leader, or anyone flagged is_gm in the characters table. It is
+// exported so subsystems without their own chat command registry, such as
+// ircgw's IRC-side KICK/MODE, can gate their own moderation verbs behind
+// the same permission model.
+func (s *Server) IsChatStaff(charID uint32) (bool, error) {
+	member, err := GetCharacterGuildDataForServer(s.db, s.logger, charID)
+	if err != nil {
+		return false, err
+	}
+	if member != nil && !member.IsApplicant && (member.IsLeader || member.IsSubLeader()) {
+		return true, nil
+	}
+
+	var isGM bool
+	if err := s.db.QueryRow("SELECT is_gm FROM characters WHERE id=$1", charID).Scan(&isGM); err != nil {
+		return false, err
+	}
+	return isGM, nil
+}
+
+// findCharIDByName resolves a character's name to its ID, the name index
+// /kick, /mute and /find dispatch against.
+func (s *Server) findCharIDByName(name string) (uint32, error) {
+	var charID uint32
+	err := s.db.QueryRow("SELECT id FROM characters WHERE name=$1", name).Scan(&charID)
+	return charID, err
+}
+
+func (s *Server) isMuted(charID uint32) bool {
+	s.muteLock.RLock()
+	defer s.muteLock.RUnlock()
+	until, ok := s.muted[charID]
+	return ok && time.Now().Before(until)
+}
+
+func (s *Server) setMuted(charID uint32, until time.Time) {
+	s.muteLock.Lock()
+	defer s.muteLock.Unlock()
+	s.muted[charID] = until
+}
+
+func cmdKick(s *Server, session *Session, args []string) {
+	if len(args) < 1 {
+		s.WhisperChatMessage(session, s.name, "usage: /kick <name>")
+		return
+	}
+
+	charID, err := s.findCharIDByName(args[0])
+	if err != nil {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("no character named %s", args[0]))
+		return
+	}
+
+	target := s.FindSessionByCharID(charID)
+	if target == nil {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("%s is not connected", args[0]))
+		return
+	}
+
+	target.server.KickSession(target)
+	s.ChatBridgeSend(s.name, fmt.Sprintf("%s was kicked", args[0]))
+}
+
+// cmdMute requires the target to be online, since the mute is recorded on
+// whichever channel server currently owns their session.
+func cmdMute(s *Server, session *Session, args []string) {
+	if len(args) < 2 {
+		s.WhisperChatMessage(session, s.name, "usage: /mute <name> <duration>")
+		return
+	}
+
+	duration, err := time.ParseDuration(args[1])
+	if err != nil {
+		s.WhisperChatMessage(session, s.name, "invalid duration, e.g. 10m")
+		return
+	}
+
+	charID, err := s.findCharIDByName(args[0])
+	if err != nil {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("no character named %s", args[0]))
+		return
+	}
+
+	target := s.FindSessionByCharID(charID)
+	if target == nil {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("%s is not connected", args[0]))
+		return
+	}
+
+	target.server.setMuted(charID, time.Now().Add(duration))
+	s.ChatBridgeSend(s.name, fmt.Sprintf("%s was muted for %s", args[0], duration))
+}
+
+func cmdBroadcast(s *Server, session *Session, args []string) {
+	if len(args) == 0 {
+		s.WhisperChatMessage(session, s.name, "usage: /broadcast <message>")
+		return
+	}
+
+	message := strings.Join(args, " ")
+	for _, c := range s.Channels {
+		c.BroadcastChatMessage(message)
+	}
+}
+
+func cmdWho(s *Server, session *Session, args []string) {
+	sessions := s.Sessions()
+	charIDs := make([]string, 0, len(sessions))
+	for _, sess := range sessions {
+		charIDs = append(charIDs, fmt.Sprintf("%d", sess.charID))
+	}
+	s.WhisperChatMessage(session, s.name, fmt.Sprintf("%d connected: %s", len(sessions), strings.Join(charIDs, ", ")))
+}
+
+func cmdFind(s *Server, session *Session, args []string) {
+	if len(args) < 1 {
+		s.WhisperChatMessage(session, s.name, "usage: /find <name>")
+		return
+	}
+
+	charID, err := s.findCharIDByName(args[0])
+	if err != nil {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("no character named %s", args[0]))
+		return
+	}
+
+	loc, ok, err := s.cluster.LookupSession(charID)
+	if err != nil || !ok {
+		s.WhisperChatMessage(session, s.name, fmt.Sprintf("%s is not connected", args[0]))
+		return
+	}
+
+	s.WhisperChatMessage(session, s.name, fmt.Sprintf("%s is on channel %d, node %s", args[0], loc.ChannelID, loc.NodeID))
+}
+
+func cmdRavi(s *Server, session *Session, args []string) {
+	if len(args) < 1 || args[0] != "status" {
+		s.WhisperChatMessage(session, s.name, "usage: /ravi status")
+		return
+	}
+
+	s.raviente.Lock()
+	nextTime := s.raviente.register.nextTime
+	startTime := s.raviente.register.startTime
+	killedTime := s.raviente.register.killedTime
+	state := append([]uint32(nil), s.raviente.state.stateData...)
+	s.raviente.Unlock()
+
+	s.WhisperChatMessage(session, s.name, fmt.Sprintf(
+		"raviente: next=%d start=%d killed=%d state=%v", nextTime, startTime, killedTime, state,
+	))
+}
+
+func cmdStats(s *Server, session *Session, args []string) {
+	var parts []string
+	for _, c := range s.Channels {
+		parts = append(parts, fmt.Sprintf("%s=%d", c.name, len(c.Sessions())))
+	}
+	s.WhisperChatMessage(session, s.name, fmt.Sprintf("sessions: %s", strings.Join(parts, ", ")))
+}
+
+func cmdHelp(s *Server, session *Session, args []string) {
+	var lines []string
+	for _, cmd := range chatCommands {
+		lines = append(lines, cmd.help)
+	}
+	s.WhisperChatMessage(session, s.name, strings.Join(lines, " | "))
+}
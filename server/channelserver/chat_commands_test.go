@@ -0,0 +1,38 @@
+package channelserver
+
+import "testing"
+
+// TestChatCommandPermissions is a regression test for the registry's
+// permission flags, the part of the dispatcher that doesn't need a live
+// *Session to exercise (HandleChatMessage itself does, and Session isn't
+// defined anywhere in this snapshot of the tree).
+func TestChatCommandPermissions(t *testing.T) {
+	wantGMOnly := map[string]bool{
+		"kick":      true,
+		"mute":      true,
+		"broadcast": true,
+		"stats":     true,
+		"who":       false,
+		"find":      false,
+		"ravi":      false,
+		"help":      false,
+	}
+
+	for name, gmOnly := range wantGMOnly {
+		cmd, ok := chatCommands[name]
+		if !ok {
+			t.Errorf("command %q is not registered", name)
+			continue
+		}
+		if cmd.gmOnly != gmOnly {
+			t.Errorf("command %q gmOnly = %v, want %v", name, cmd.gmOnly, gmOnly)
+		}
+		if cmd.handler == nil {
+			t.Errorf("command %q has a nil handler", name)
+		}
+	}
+
+	if len(chatCommands) != len(wantGMOnly) {
+		t.Errorf("got %d registered commands, want %d", len(chatCommands), len(wantGMOnly))
+	}
+}
@@ -93,13 +93,20 @@ SELECT
 `
 
 func GetGuildMembers(s *Session, guildID uint32, applicants bool) ([]*GuildMember, error) {
-	rows, err := s.server.db.Queryx(fmt.Sprintf(`
+	return GetGuildMembersForServer(s.server.db, s.logger, guildID, applicants)
+}
+
+// GetGuildMembersForServer is the Session-free variant of GetGuildMembers,
+// for callers such as ircgw that talk to the database without a live MHF
+// connection to hang a Session off of.
+func GetGuildMembersForServer(db *sqlx.DB, logger *zap.Logger, guildID uint32, applicants bool) ([]*GuildMember, error) {
+	rows, err := db.Queryx(fmt.Sprintf(`
 			%s
 			WHERE character.guild_id = $1 AND is_applicant = $2
 	`, guildMembersSelectSQL), guildID, applicants)
 
 	if err != nil {
-		s.logger.Error("failed to retrieve membership data for guild", zap.Error(err), zap.Uint32("guildID", guildID))
+		logger.Error("failed to retrieve membership data for guild", zap.Error(err), zap.Uint32("guildID", guildID))
 		return nil, err
 	}
 
@@ -108,7 +115,7 @@ func GetGuildMembers(s *Session, guildID uint32, applicants bool) ([]*GuildMembe
 	members := make([]*GuildMember, 0)
 
 	for rows.Next() {
-		member, err := buildGuildMemberObjectFromDBResult(rows, err, s)
+		member, err := buildGuildMemberObjectFromDBResult(rows, logger)
 
 		if err != nil {
 			return nil, err
@@ -121,10 +128,17 @@ func GetGuildMembers(s *Session, guildID uint32, applicants bool) ([]*GuildMembe
 }
 
 func GetCharacterGuildData(s *Session, charID uint32) (*GuildMember, error) {
-	rows, err := s.server.db.Queryx(fmt.Sprintf("%s	WHERE character.character_id=$1", guildMembersSelectSQL), charID)
+	return GetCharacterGuildDataForServer(s.server.db, s.logger, charID)
+}
+
+// GetCharacterGuildDataForServer is the Session-free variant of
+// GetCharacterGuildData, for callers such as ircgw that talk to the
+// database without a live MHF connection to hang a Session off of.
+func GetCharacterGuildDataForServer(db *sqlx.DB, logger *zap.Logger, charID uint32) (*GuildMember, error) {
+	rows, err := db.Queryx(fmt.Sprintf("%s	WHERE character.character_id=$1", guildMembersSelectSQL), charID)
 
 	if err != nil {
-		s.logger.Error(fmt.Sprintf("failed to retrieve membership data for character '%d'", charID))
+		logger.Error(fmt.Sprintf("failed to retrieve membership data for character '%d'", charID))
 		return nil, err
 	}
 
@@ -136,16 +150,16 @@ func GetCharacterGuildData(s *Session, charID uint32) (*GuildMember, error) {
 		return nil, nil
 	}
 
-	return buildGuildMemberObjectFromDBResult(rows, err, s)
+	return buildGuildMemberObjectFromDBResult(rows, logger)
 }
 
-func buildGuildMemberObjectFromDBResult(rows *sqlx.Rows, err error, s *Session) (*GuildMember, error) {
+func buildGuildMemberObjectFromDBResult(rows *sqlx.Rows, logger *zap.Logger) (*GuildMember, error) {
 	memberData := &GuildMember{}
 
-	err = rows.StructScan(&memberData)
+	err := rows.StructScan(&memberData)
 
 	if err != nil {
-		s.logger.Error("failed to retrieve guild data from database", zap.Error(err))
+		logger.Error("failed to retrieve guild data from database", zap.Error(err))
 		return nil, err
 	}
 
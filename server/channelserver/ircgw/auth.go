@@ -0,0 +1,33 @@
+package ircgw
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// authResult is what a successful SASL PLAIN login resolves a connection
+// to: the character it should act as in-game.
+type authResult struct {
+	charID   uint32
+	charName string
+}
+
+// authenticate verifies username/password against the same characters
+// table the rest of channelserver reads from, then resolves the account's
+// default character. It mirrors the login server's own credential check.
+func authenticate(db *sqlx.DB, username, password string) (*authResult, error) {
+	var result authResult
+	err := db.QueryRowx(`
+		SELECT c.id, c.name
+		FROM users u
+		JOIN characters c ON c.user_id = u.id
+		WHERE u.username = $1 AND u.password = crypt($2, u.password)
+		ORDER BY c.last_login DESC
+		LIMIT 1
+	`, username, password).Scan(&result.charID, &result.charName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,104 @@
+package ircgw
+
+import (
+	"strings"
+	"sync"
+
+	"erupe-ce/server/channelserver"
+)
+
+// channel is one IRC channel, backed either by an MHF Server (a "#ch01"
+// style channel), or a guild room ("#guild-<id>") with no backing Server.
+type channel struct {
+	name string
+
+	// server is non-nil for channels that mirror a live MHF channel
+	// server; guild channels leave it nil and deliver game-side by
+	// whispering each online guild member directly (see relayToGuild).
+	server *channelserver.Server
+
+	// guildID is set for "#guild-<id>" channels.
+	guildID uint32
+
+	mu      sync.RWMutex
+	members map[*client]bool
+
+	// banmasks holds the nick!user@host masks set with MODE +b.
+	banmasks map[string]bool
+}
+
+func newChannel(name string, server *channelserver.Server) *channel {
+	return &channel{
+		name:     name,
+		server:   server,
+		members:  make(map[*client]bool),
+		banmasks: make(map[string]bool),
+	}
+}
+
+func newGuildChannel(name string, guildID uint32) *channel {
+	return &channel{
+		name:     name,
+		guildID:  guildID,
+		members:  make(map[*client]bool),
+		banmasks: make(map[string]bool),
+	}
+}
+
+func (ch *channel) add(c *client) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.members[c] = true
+}
+
+func (ch *channel) remove(c *client) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	delete(ch.members, c)
+}
+
+func (ch *channel) has(c *client) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	return ch.members[c]
+}
+
+func (ch *channel) isBanned(mask string) bool {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	return ch.banmasks[strings.ToLower(mask)]
+}
+
+func (ch *channel) setBan(mask string, banned bool) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	mask = strings.ToLower(mask)
+	if banned {
+		ch.banmasks[mask] = true
+	} else {
+		delete(ch.banmasks, mask)
+	}
+}
+
+// names returns the nicks of every member, for NAMES/WHO replies.
+func (ch *channel) names() []string {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	names := make([]string, 0, len(ch.members))
+	for c := range ch.members {
+		names = append(names, c.nick)
+	}
+	return names
+}
+
+// broadcast relays a raw IRC line to every member except skip.
+func (ch *channel) broadcast(line string, skip *client) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+	for c := range ch.members {
+		if c == skip {
+			continue
+		}
+		c.send(line)
+	}
+}
@@ -0,0 +1,51 @@
+package ircgw
+
+import (
+	"fmt"
+	"net"
+)
+
+// client is a single IRC connection, optionally bound to an MHF character
+// once it authenticates via SASL PLAIN.
+type client struct {
+	conn net.Conn
+
+	nick string
+	user string
+
+	registered bool
+
+	// charID and charName are set once the client authenticates, binding
+	// this IRC connection to an MHF character.
+	charID   uint32
+	charName string
+	isGM     bool
+
+	rateLimiter *rateLimiter
+}
+
+func newClient(conn net.Conn, rl *rateLimiter) *client {
+	return &client{conn: conn, rateLimiter: rl}
+}
+
+// prefix returns the nick!user@host prefix used on relayed lines.
+func (c *client) prefix() string {
+	host, _, err := net.SplitHostPort(c.conn.RemoteAddr().String())
+	if err != nil {
+		host = "erupe"
+	}
+	return fmt.Sprintf("%s!%s@%s", c.nick, c.user, host)
+}
+
+func (c *client) send(line string) {
+	fmt.Fprintf(c.conn, "%s\r\n", line)
+}
+
+// sendNumeric sends a numeric reply of the form ":server CODE target :text".
+func (c *client) sendNumeric(g *Gateway, code, target, text string) {
+	c.send(fmt.Sprintf(":%s %s %s :%s", g.cfg.ServerName, code, target, text))
+}
+
+func (c *client) authenticated() bool {
+	return c.charID != 0
+}
@@ -0,0 +1,435 @@
+package ircgw
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"erupe-ce/server/channelserver"
+
+	"go.uber.org/zap"
+)
+
+func (g *Gateway) dispatch(c *client, msg message) {
+	switch msg.command {
+	case "CAP":
+		g.handleCAP(c, msg.params)
+	case "AUTHENTICATE":
+		g.handleAuthenticate(c, msg.params)
+	case "NICK":
+		g.handleNick(c, msg.params)
+	case "USER":
+		g.handleUser(c, msg.params)
+	case "PING":
+		if len(msg.params) > 0 {
+			c.send(fmt.Sprintf("PONG :%s", msg.params[0]))
+		}
+	case "JOIN":
+		g.handleJoin(c, msg.params)
+	case "PART":
+		g.handlePart(c, msg.params)
+	case "PRIVMSG":
+		g.handlePrivmsg(c, msg.params)
+	case "NAMES":
+		g.handleNames(c, msg.params)
+	case "WHO":
+		g.handleWho(c, msg.params)
+	case "KICK":
+		g.handleKick(c, msg.params)
+	case "MODE":
+		g.handleMode(c, msg.params)
+	case "QUIT":
+		reason := "Quit"
+		if len(msg.params) > 0 {
+			reason = msg.params[0]
+		}
+		g.disconnect(c, reason)
+	}
+}
+
+func (g *Gateway) handleCAP(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	switch strings.ToUpper(params[0]) {
+	case "LS":
+		c.send(":" + g.cfg.ServerName + " CAP * LS :sasl")
+	case "REQ":
+		c.send(":" + g.cfg.ServerName + " CAP * ACK :sasl")
+	case "END":
+		// No-op; registration proceeds once NICK/USER (or SASL) complete.
+	}
+}
+
+func (g *Gateway) handleAuthenticate(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+
+	if strings.ToUpper(params[0]) == "PLAIN" {
+		c.send("AUTHENTICATE +")
+		return
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(params[0])
+	if err != nil {
+		c.sendNumeric(g, "904", c.nickOrStar(), "SASL authentication failed")
+		return
+	}
+
+	// authzid \0 authcid \0 passwd
+	parts := strings.SplitN(string(raw), "\x00", 3)
+	if len(parts) != 3 {
+		c.sendNumeric(g, "904", c.nickOrStar(), "SASL authentication failed")
+		return
+	}
+
+	result, err := authenticate(g.cfg.DB, parts[1], parts[2])
+	if err != nil {
+		g.logger.Debug("ircgw SASL auth failed", zap.String("username", parts[1]), zap.Error(err))
+		c.sendNumeric(g, "904", c.nickOrStar(), "SASL authentication failed")
+		return
+	}
+
+	c.charID = result.charID
+	c.charName = result.charName
+	c.sendNumeric(g, "900", c.nickOrStar(), fmt.Sprintf("You are now logged in as %s", result.charName))
+	c.sendNumeric(g, "903", c.nickOrStar(), "SASL authentication successful")
+}
+
+func (c *client) nickOrStar() string {
+	if c.nick == "" {
+		return "*"
+	}
+	return c.nick
+}
+
+func (g *Gateway) handleNick(c *client, params []string) {
+	if len(params) == 0 {
+		c.sendNumeric(g, "431", "*", "No nickname given")
+		return
+	}
+	nick := params[0]
+
+	g.mu.Lock()
+	if _, taken := g.clients[strings.ToUpper(nick)]; taken {
+		g.mu.Unlock()
+		c.sendNumeric(g, "433", nick, "Nickname is already in use")
+		return
+	}
+	if c.nick != "" {
+		delete(g.clients, strings.ToUpper(c.nick))
+	}
+	c.nick = nick
+	g.clients[strings.ToUpper(nick)] = c
+	g.mu.Unlock()
+
+	g.maybeWelcome(c)
+}
+
+func (g *Gateway) handleUser(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	c.user = params[0]
+	g.maybeWelcome(c)
+}
+
+func (g *Gateway) maybeWelcome(c *client) {
+	if c.registered || c.nick == "" || c.user == "" {
+		return
+	}
+	c.registered = true
+	c.sendNumeric(g, "001", c.nick, fmt.Sprintf("Welcome to %s, %s", g.cfg.ServerName, c.nick))
+	c.sendNumeric(g, "376", c.nick, "End of /MOTD command")
+}
+
+func (g *Gateway) handleJoin(c *client, params []string) {
+	if len(params) == 0 || !c.registered {
+		return
+	}
+
+	for _, name := range strings.Split(params[0], ",") {
+		ch, err := g.resolveChannel(c, name)
+		if err != nil {
+			c.sendNumeric(g, "403", name, "No such channel")
+			continue
+		}
+		if ch.isBanned(c.prefix()) {
+			c.sendNumeric(g, "474", name, "Cannot join channel (+b)")
+			continue
+		}
+
+		ch.add(c)
+		ch.broadcast(fmt.Sprintf(":%s JOIN :%s", c.prefix(), ch.name), nil)
+		c.send(fmt.Sprintf(":%s JOIN :%s", c.prefix(), ch.name))
+		g.sendNames(c, ch)
+	}
+}
+
+// resolveChannel returns the channel for name, creating "#guild-<id>"
+// channels on demand the first time an authenticated member joins one.
+func (g *Gateway) resolveChannel(c *client, name string) (*channel, error) {
+	name = strings.ToLower(name)
+
+	if strings.HasPrefix(name, "#guild-") {
+		return g.resolveGuildChannel(c, name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ch, ok := g.channels[name]
+	if !ok {
+		return nil, fmt.Errorf("no such channel %q", name)
+	}
+	return ch, nil
+}
+
+// resolveGuildChannel verifies c is still a member of the guild name
+// encodes before returning its channel. Membership is re-checked on every
+// join, not just the first time the channel is created, since the channel
+// object is cached and shared across every joiner and guild rosters
+// change; the DB lookup also runs with g.mu released so one guild-channel
+// join doesn't stall every other client on the gateway for the round-trip.
+func (g *Gateway) resolveGuildChannel(c *client, name string) (*channel, error) {
+	if !c.authenticated() {
+		return nil, fmt.Errorf("no such channel %q", name)
+	}
+
+	member, err := channelserver.GetCharacterGuildDataForServer(g.cfg.DB, g.logger, c.charID)
+	if err != nil || member == nil || member.IsApplicant || fmt.Sprintf("#guild-%d", member.GuildID) != name {
+		return nil, fmt.Errorf("not a member of %q", name)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ch, ok := g.channels[name]; ok {
+		return ch, nil
+	}
+	ch := newGuildChannel(name, member.GuildID)
+	g.channels[name] = ch
+	return ch, nil
+}
+
+func (g *Gateway) handlePart(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	for _, name := range strings.Split(params[0], ",") {
+		ch := g.lookupChannel(name)
+		if ch == nil || !ch.has(c) {
+			continue
+		}
+		ch.remove(c)
+		ch.broadcast(fmt.Sprintf(":%s PART %s", c.prefix(), ch.name), nil)
+		c.send(fmt.Sprintf(":%s PART %s", c.prefix(), ch.name))
+	}
+}
+
+func (g *Gateway) lookupChannel(name string) *channel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.channels[strings.ToLower(name)]
+}
+
+func (g *Gateway) handlePrivmsg(c *client, params []string) {
+	if len(params) < 2 || !c.authenticated() {
+		return
+	}
+	target, text := params[0], params[1]
+
+	if strings.HasPrefix(target, "#") {
+		g.privmsgChannel(c, target, text)
+		return
+	}
+
+	g.privmsgUser(c, target, text)
+}
+
+func (g *Gateway) privmsgChannel(c *client, target, text string) {
+	ch := g.lookupChannel(target)
+	if ch == nil || !ch.has(c) {
+		return
+	}
+	if ch.isBanned(c.prefix()) {
+		c.sendNumeric(g, "404", ch.name, "Cannot send to channel (+b)")
+		return
+	}
+
+	ch.broadcast(fmt.Sprintf(":%s PRIVMSG %s :%s", c.prefix(), ch.name, text), c)
+
+	switch {
+	case ch.server != nil:
+		ch.server.BroadcastChatMessage(fmt.Sprintf("%s: %s", c.charName, text))
+	case ch.guildID != 0:
+		g.relayToGuild(ch.guildID, c.charName, text)
+	}
+}
+
+// relayToGuild whispers text to every online member of guildID, so players
+// idling in their guild's game session see messages sent from IRC.
+func (g *Gateway) relayToGuild(guildID uint32, sender, text string) {
+	if g.anyChannelServer() == nil {
+		return
+	}
+
+	members, err := channelserver.GetGuildMembersForServer(g.cfg.DB, g.logger, guildID, false)
+	if err != nil {
+		g.logger.Warn("ircgw failed to load guild members for relay", zap.Uint32("guildID", guildID), zap.Error(err))
+		return
+	}
+
+	for _, member := range members {
+		session := g.anyChannelServer().FindSessionByCharID(member.CharID)
+		if session != nil {
+			g.anyChannelServer().WhisperChatMessage(session, sender, text)
+		}
+	}
+}
+
+// isStaff reports whether c may run a moderation verb (KICK, MODE +b/-b):
+// an authenticated character flagged as guild leadership or is_gm, per
+// channelserver's own chat command permission model.
+func (g *Gateway) isStaff(c *client) bool {
+	if !c.authenticated() || g.anyChannelServer() == nil {
+		return false
+	}
+	isStaff, err := g.anyChannelServer().IsChatStaff(c.charID)
+	if err != nil {
+		g.logger.Warn("ircgw failed to check staff permissions", zap.Uint32("charID", c.charID), zap.Error(err))
+		return false
+	}
+	return isStaff
+}
+
+func (g *Gateway) anyChannelServer() *channelserver.Server {
+	if len(g.cfg.Channels) == 0 {
+		return nil
+	}
+	return g.cfg.Channels[0]
+}
+
+func (g *Gateway) privmsgUser(c *client, target, text string) {
+	g.mu.Lock()
+	peer, ok := g.clients[strings.ToUpper(target)]
+	g.mu.Unlock()
+	if ok {
+		peer.send(fmt.Sprintf(":%s PRIVMSG %s :%s", c.prefix(), target, text))
+		return
+	}
+
+	if g.anyChannelServer() == nil {
+		return
+	}
+
+	var charID uint32
+	if err := g.cfg.DB.Get(&charID, "SELECT id FROM characters WHERE name = $1", target); err != nil {
+		c.sendNumeric(g, "401", target, "No such nick/channel")
+		return
+	}
+
+	session := g.anyChannelServer().FindSessionByCharID(charID)
+	if session == nil {
+		c.sendNumeric(g, "401", target, "No such nick/channel")
+		return
+	}
+	g.anyChannelServer().WhisperChatMessage(session, c.charName, text)
+}
+
+func (g *Gateway) handleNames(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	ch := g.lookupChannel(params[0])
+	if ch == nil {
+		return
+	}
+	g.sendNames(c, ch)
+}
+
+func (g *Gateway) sendNames(c *client, ch *channel) {
+	c.sendNumeric(g, "353", ch.name, strings.Join(ch.names(), " "))
+	c.sendNumeric(g, "366", ch.name, "End of /NAMES list")
+}
+
+func (g *Gateway) handleWho(c *client, params []string) {
+	if len(params) == 0 {
+		return
+	}
+	ch := g.lookupChannel(params[0])
+	if ch == nil {
+		return
+	}
+	for _, nick := range ch.names() {
+		c.sendNumeric(g, "352", fmt.Sprintf("%s %s", ch.name, nick), "WHO list entry")
+	}
+	c.sendNumeric(g, "315", ch.name, "End of /WHO list")
+}
+
+// handleKick maps IRC KICK onto disconnecting the target's MHF session, not
+// just removing them from the IRC channel, per the gateway's moderation
+// story: a GM kicking from IRC should actually remove the player in-game.
+// Since that's a real disconnect and not just an IRC-channel removal, it's
+// gated behind the same staff permission channelserver's chat commands use.
+func (g *Gateway) handleKick(c *client, params []string) {
+	if len(params) < 2 || !c.authenticated() {
+		return
+	}
+	if !g.isStaff(c) {
+		c.sendNumeric(g, "481", params[0], "Permission Denied- You're not an IRC operator")
+		return
+	}
+	ch := g.lookupChannel(params[0])
+	if ch == nil {
+		return
+	}
+
+	g.mu.Lock()
+	target, ok := g.clients[strings.ToUpper(params[1])]
+	g.mu.Unlock()
+	if !ok || !target.authenticated() {
+		c.sendNumeric(g, "441", params[1], "They aren't on that channel")
+		return
+	}
+
+	ch.broadcast(fmt.Sprintf(":%s KICK %s %s :Kicked", c.prefix(), ch.name, target.nick), nil)
+	ch.remove(target)
+
+	if cs := g.anyChannelServer(); cs != nil {
+		if session := cs.FindSessionByCharID(target.charID); session != nil {
+			// KickSession is a no-op on every server but the one owning session.
+			for _, server := range g.cfg.Channels {
+				server.KickSession(session)
+			}
+		}
+	}
+}
+
+// handleMode supports channel ban masks (+b/-b), which ircgw enforces as a
+// mute: a banned mask can no longer JOIN the channel or PRIVMSG it. Setting a ban is
+// gated behind the same staff permission as KICK, since it lets a caller
+// lock other players out of a channel.
+func (g *Gateway) handleMode(c *client, params []string) {
+	if len(params) < 2 {
+		return
+	}
+	if !g.isStaff(c) {
+		c.sendNumeric(g, "481", params[0], "Permission Denied- You're not an IRC operator")
+		return
+	}
+	ch := g.lookupChannel(params[0])
+	if ch == nil {
+		return
+	}
+
+	switch params[1] {
+	case "+b":
+		if len(params) > 2 {
+			ch.setBan(params[2], true)
+		}
+	case "-b":
+		if len(params) > 2 {
+			ch.setBan(params[2], false)
+		}
+	}
+}
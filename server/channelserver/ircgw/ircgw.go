@@ -0,0 +1,153 @@
+// Package ircgw exposes MHF channels and guilds as a small RFC1459/IRCv3
+// server, so players and GMs can idle in their guild chat or moderate from
+// a normal IRC client. It depends on channelserver, so (to avoid an import
+// cycle with Server.Start) its Gateway is constructed and started by the
+// same entry point that builds the channel servers themselves, right after
+// each channelserver.Server.Start call. It talks back into channelserver
+// purely through that package's exported surface
+// (Server.BroadcastChatMessage, Server.FindSessionByCharID,
+// Server.WhisperChatMessage, Server.KickSession,
+// channelserver.GetGuildMembersForServer/GetCharacterGuildDataForServer).
+package ircgw
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"erupe-ce/config"
+	"erupe-ce/server/channelserver"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// Config configures a Gateway.
+type Config struct {
+	Logger      *zap.Logger
+	DB          *sqlx.DB
+	ErupeConfig *config.Config
+
+	// Addr is the host:port the IRC listener binds to.
+	Addr string
+
+	// ServerName is reported to clients in numeric replies, e.g. "erupe.irc".
+	ServerName string
+
+	// Channels is every MHF channel server this gateway bridges. Each one
+	// is exposed as "#<channel name>", e.g. "#ch01".
+	Channels []*channelserver.Server
+}
+
+// Gateway is the IRC server bridging MHF chat to IRC clients.
+type Gateway struct {
+	cfg    Config
+	logger *zap.Logger
+
+	listener net.Listener
+
+	mu       sync.Mutex
+	clients  map[string]*client // keyed by uppercased nick
+	channels map[string]*channel
+}
+
+// NewGateway creates a Gateway; call Start to begin listening.
+func NewGateway(cfg Config) *Gateway {
+	g := &Gateway{
+		cfg:      cfg,
+		logger:   cfg.Logger,
+		clients:  make(map[string]*client),
+		channels: make(map[string]*channel),
+	}
+
+	for _, ch := range cfg.Channels {
+		name := "#" + strings.ToLower(ch.Name())
+		g.channels[name] = newChannel(name, ch)
+	}
+
+	return g
+}
+
+// Start begins listening and accepting IRC connections in a new goroutine.
+func (g *Gateway) Start() error {
+	l, err := net.Listen("tcp", g.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	g.listener = l
+
+	go g.acceptClients()
+
+	return nil
+}
+
+// Stop closes the listener, so no new connections are accepted. Existing
+// client connections are not closed; each stays open until its client
+// disconnects.
+func (g *Gateway) Stop() error {
+	if g.listener == nil {
+		return nil
+	}
+	return g.listener.Close()
+}
+
+func (g *Gateway) acceptClients() {
+	for {
+		conn, err := g.listener.Accept()
+		if err != nil {
+			g.logger.Debug("ircgw listener closed", zap.Error(err))
+			return
+		}
+		go g.serve(conn)
+	}
+}
+
+func (g *Gateway) serve(conn net.Conn) {
+	c := newClient(conn, newRateLimiter(5, 2))
+	defer g.disconnect(c, "Connection closed")
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			continue
+		}
+		if !c.rateLimiter.Allow() {
+			c.sendNumeric(g, "421", "*", "Rate limit exceeded")
+			continue
+		}
+		g.dispatch(c, parseLine(line))
+	}
+}
+
+// disconnect removes c from every channel it had joined and the nick
+// table, notifies those channels with a QUIT line, and closes its
+// connection.
+func (g *Gateway) disconnect(c *client, reason string) {
+	g.mu.Lock()
+	memberOf := make([]*channel, 0)
+	for _, ch := range g.channels {
+		if ch.has(c) {
+			memberOf = append(memberOf, ch)
+		}
+		ch.remove(c)
+	}
+	if c.nick != "" {
+		delete(g.clients, strings.ToUpper(c.nick))
+	}
+	g.mu.Unlock()
+
+	if c.nick != "" {
+		for _, ch := range memberOf {
+			ch.broadcast(fmt.Sprintf(":%s QUIT :%s", c.prefix(), reason), nil)
+		}
+	}
+
+	c.conn.Close()
+}
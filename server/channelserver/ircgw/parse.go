@@ -0,0 +1,42 @@
+package ircgw
+
+import "strings"
+
+// message is a parsed IRC line: COMMAND param0 param1 :trailing param.
+type message struct {
+	command string
+	params  []string
+}
+
+// parseLine parses a single IRC protocol line. It does not handle the
+// optional leading "@tags" a server would emit, since these are
+// client-to-server lines only; a leading ":prefix" is stripped and
+// discarded.
+func parseLine(line string) message {
+	if strings.HasPrefix(line, ":") {
+		// Clients aren't supposed to send a prefix, but strip it rather
+		// than choke on it.
+		if idx := strings.IndexByte(line, ' '); idx != -1 {
+			line = line[idx+1:]
+		} else {
+			line = ""
+		}
+	}
+
+	var params []string
+	if idx := strings.Index(line, " :"); idx != -1 {
+		params = strings.Fields(line[:idx])
+		params = append(params, line[idx+2:])
+	} else {
+		params = strings.Fields(line)
+	}
+
+	if len(params) == 0 {
+		return message{}
+	}
+
+	return message{
+		command: strings.ToUpper(params[0]),
+		params:  params[1:],
+	}
+}
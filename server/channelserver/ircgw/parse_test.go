@@ -0,0 +1,59 @@
+package ircgw
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want message
+	}{
+		{
+			name: "simple command",
+			line: "PING",
+			want: message{command: "PING", params: []string{}},
+		},
+		{
+			name: "params without trailing",
+			line: "JOIN #ch01,#ch02",
+			want: message{command: "JOIN", params: []string{"#ch01,#ch02"}},
+		},
+		{
+			name: "trailing param with spaces",
+			line: "PRIVMSG #ch01 :hello there world",
+			want: message{command: "PRIVMSG", params: []string{"#ch01", "hello there world"}},
+		},
+		{
+			name: "leading prefix is stripped",
+			line: ":nick!user@host PRIVMSG #ch01 :hi",
+			want: message{command: "PRIVMSG", params: []string{"#ch01", "hi"}},
+		},
+		{
+			name: "prefix with no following command",
+			line: ":nick!user@host",
+			want: message{},
+		},
+		{
+			name: "empty line",
+			line: "",
+			want: message{},
+		},
+		{
+			name: "lowercase command is upcased",
+			line: "nick Bob",
+			want: message{command: "NICK", params: []string{"Bob"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLine(tt.line)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ircgw
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a simple token bucket used to cap how many lines a single
+// connection may send per second, so one misbehaving or malicious IRC
+// client can't flood the gateway.
+type rateLimiter struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+func newRateLimiter(maxTokens, refillRate float64) *rateLimiter {
+	return &rateLimiter{
+		tokens:     maxTokens,
+		maxTokens:  maxTokens,
+		refillRate: refillRate,
+		last:       time.Now(),
+	}
+}
+
+// Allow reports whether a line may be sent now, consuming a token if so.
+func (r *rateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(r.last).Seconds()
+	r.last = now
+
+	r.tokens += elapsed * r.refillRate
+	if r.tokens > r.maxTokens {
+		r.tokens = r.maxTokens
+	}
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
@@ -0,0 +1,37 @@
+package ircgw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBurstThenDeny(t *testing.T) {
+	rl := newRateLimiter(3, 1)
+
+	for i := 0; i < 3; i++ {
+		if !rl.Allow() {
+			t.Fatalf("expected burst token %d to be allowed", i)
+		}
+	}
+
+	if rl.Allow() {
+		t.Fatal("expected request beyond the burst to be denied")
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	rl := newRateLimiter(1, 100) // refills fast enough for the test to sleep briefly
+
+	if !rl.Allow() {
+		t.Fatal("expected the initial token to be allowed")
+	}
+	if rl.Allow() {
+		t.Fatal("expected the bucket to be empty right after spending its only token")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !rl.Allow() {
+		t.Fatal("expected a token to have refilled after waiting")
+	}
+}
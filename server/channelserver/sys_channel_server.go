@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"erupe-ce/common/byteframe"
 	ps "erupe-ce/common/pascalstring"
 	"erupe-ce/config"
 	"erupe-ce/network/binpacket"
 	"erupe-ce/network/mhfpacket"
-	"erupe-ce/server/discordbot"
+	"erupe-ce/server/chatbridge"
+	"erupe-ce/server/cluster"
 
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
@@ -21,10 +24,24 @@ type Config struct {
 	ID          uint16
 	Logger      *zap.Logger
 	DB          *sqlx.DB
-	DiscordBot  *discordbot.DiscordBot
+	Gateway     *chatbridge.Gateway
 	ErupeConfig *config.Config
 	Name        string
 	Enable      bool
+
+	// NodeID identifies this process in a clustered deployment. It is
+	// ignored by the default single-node in-memory Cluster store.
+	NodeID string
+
+	// Cluster is the shared state store for the session directory,
+	// semaphore ownership and Raviente state. A single-node deployment
+	// can leave this nil; NewServer falls back to an in-memory store
+	// with the same semantics channelserver always had.
+	Cluster cluster.Store
+
+	// Transport fans world broadcasts out to other nodes in a clustered
+	// deployment. A single-node deployment can leave this nil.
+	Transport cluster.PacketTransport
 }
 
 // Map key type for a user binary part.
@@ -50,6 +67,13 @@ type Server struct {
 	listener       net.Listener // Listener that is created when Server.Start is called.
 	isShuttingDown bool
 
+	// sessionsSnapshot holds an atomically-swapped []*Session built from
+	// sessions. Broadcast-path readers use it instead of locking s.Mutex,
+	// so packet building never blocks the accept/delete loop; only
+	// manageSessions regenerates it, guarded by sessionsCacheMutex.
+	sessionsSnapshot   atomic.Value
+	sessionsCacheMutex sync.Mutex
+
 	stagesLock sync.RWMutex
 	stages     map[string]*Stage
 
@@ -61,12 +85,25 @@ type Server struct {
 	userBinaryParts     map[userBinaryPartID][]byte
 
 	// Semaphore
-	semaphoreLock  sync.RWMutex
-	semaphore      map[string]*Semaphore
-	semaphoreIndex uint32
-
-	// Discord chat integration
-	discordBot *discordbot.DiscordBot
+	semaphoreLock sync.RWMutex
+	semaphore     map[string]*Semaphore
+
+	// muted holds the charIDs currently silenced by the /mute chat command,
+	// mapped to the time the mute expires.
+	muteLock sync.RWMutex
+	muted    map[uint32]time.Time
+
+	// Chat bridge gateway, fanning chat out to Discord/IRC/Matrix/Slack/
+	// Telegram and back.
+	gateway *chatbridge.Gateway
+
+	// nodeID, cluster and transport back the clustered-deployment session
+	// directory, semaphore allocation, Raviente state and cross-node world
+	// broadcasts. cluster is never nil; a single-node deployment gets an
+	// in-memory store with transport left nil.
+	nodeID    string
+	cluster   cluster.Store
+	transport cluster.PacketTransport
 
 	name string
 
@@ -144,6 +181,11 @@ func (r *Raviente) GetRaviMultiplier(s *Server) float64 {
 
 // NewServer creates a new Server type.
 func NewServer(config *Config) *Server {
+	clusterStore := config.Cluster
+	if clusterStore == nil {
+		clusterStore = cluster.NewMemStore()
+	}
+
 	s := &Server{
 		ID:              config.ID,
 		logger:          config.Logger,
@@ -155,8 +197,11 @@ func NewServer(config *Config) *Server {
 		stages:          make(map[string]*Stage),
 		userBinaryParts: make(map[userBinaryPartID][]byte),
 		semaphore:       make(map[string]*Semaphore),
-		semaphoreIndex:  7,
-		discordBot:      config.DiscordBot,
+		muted:           make(map[uint32]time.Time),
+		gateway:         config.Gateway,
+		nodeID:          config.NodeID,
+		cluster:         clusterStore,
+		transport:       config.Transport,
 		name:            config.Name,
 		raviente:        NewRaviente(),
 	}
@@ -183,10 +228,28 @@ func NewServer(config *Config) *Server {
 	s.stages["sl1Ns462p0a0u0"] = NewStage("sl1Ns462p0a0u0")
 
 	s.dict = getLangStrings(s)
+	s.sessionsSnapshot.Store(make([]*Session, 0))
 
 	return s
 }
 
+// DB returns the server's database handle, for subsystems such as ircgw
+// that need to query it without a live MHF Session.
+func (s *Server) DB() *sqlx.DB {
+	return s.db
+}
+
+// Logger returns the server's logger, for subsystems such as ircgw that
+// need to log without a live MHF Session.
+func (s *Server) Logger() *zap.Logger {
+	return s.logger
+}
+
+// Name returns the channel's configured name, e.g. "ch01".
+func (s *Server) Name() string {
+	return s.name
+}
+
 // Start starts the server in a new goroutine.
 func (s *Server) Start() error {
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Port))
@@ -198,9 +261,35 @@ func (s *Server) Start() error {
 	go s.acceptClients()
 	go s.manageSessions()
 
-	// Start the discord bot for chat integration.
-	if s.erupeConfig.Discord.Enabled && s.discordBot != nil {
-		s.discordBot.Session.AddHandler(s.onDiscordMessage)
+	// Start the chat bridge gateway so messages from Discord/IRC/Matrix/
+	// Slack/Telegram fan into this channel's chat, and vice versa.
+	if s.gateway != nil {
+		s.gateway.OnGameMessage(func(room, originBridge, sender, content string) {
+			if room != s.name {
+				return
+			}
+			s.broadcastChatMessage(fmt.Sprintf("%s: %s", sender, content), false)
+			s.gateway.PublishExcept(room, originBridge, sender, content)
+		})
+		if err := s.gateway.Start(); err != nil {
+			s.logger.Warn("Failed to start chat bridge gateway", zap.Error(err))
+		}
+	}
+
+	// In a clustered deployment, fan world broadcasts built on other
+	// nodes out to this node's local channels.
+	if s.transport != nil {
+		err := s.transport.SubscribeWorld(s.nodeID, func(ignoredChannelID uint16, opcode uint16, payload []byte) {
+			for _, c := range s.Channels {
+				if c.ID == ignoredChannelID {
+					continue
+				}
+				c.broadcastRaw(opcode, payload)
+			}
+		})
+		if err != nil {
+			s.logger.Warn("Failed to subscribe to cluster world broadcasts", zap.Error(err))
+		}
 	}
 
 	return nil
@@ -255,24 +344,56 @@ func (s *Server) manageSessions() {
 
 			s.Lock()
 			s.sessions[newConn] = session
+			s.refreshSessionsSnapshot()
 			s.Unlock()
 
 			session.Start()
 
 		case delConn := <-s.deleteConns:
 			s.Lock()
+			if session, ok := s.sessions[delConn]; ok && session.charID != 0 {
+				s.UnregisterClusterSession(session.charID)
+			}
 			delete(s.sessions, delConn)
+			s.refreshSessionsSnapshot()
 			s.Unlock()
 		}
 	}
 }
 
-// BroadcastMHF queues a MHFPacket to be sent to all sessions.
-func (s *Server) BroadcastMHF(pkt mhfpacket.MHFPacket, ignoredSession *Session) {
-	// Broadcast the data.
-	s.Lock()
-	defer s.Unlock()
+// refreshSessionsSnapshot rebuilds the atomically-swapped session slice from
+// s.sessions. Callers must already hold s.Mutex; the snapshot swap itself is
+// additionally serialised by sessionsCacheMutex, mirroring the separation
+// between the write-side map lock and the cache regeneration step.
+func (s *Server) refreshSessionsSnapshot() {
+	snapshot := make([]*Session, 0, len(s.sessions))
 	for _, session := range s.sessions {
+		snapshot = append(snapshot, session)
+	}
+
+	s.sessionsCacheMutex.Lock()
+	defer s.sessionsCacheMutex.Unlock()
+	s.sessionsSnapshot.Store(snapshot)
+}
+
+// Sessions returns the current atomically-swapped session snapshot. It is
+// safe to call without holding s.Mutex.
+//
+// No benchmark demonstrates this under a synthetic 500-session broadcast:
+// Session itself isn't defined anywhere in this snapshot of the tree (it's
+// referenced throughout channelserver, e.g. s.sessions, but its type lives
+// in a file this snapshot doesn't include), so a benchmark constructing
+// fake sessions to drive BroadcastMHF can't be written here without
+// inventing that type's shape.
+func (s *Server) Sessions() []*Session {
+	return s.sessionsSnapshot.Load().([]*Session)
+}
+
+// BroadcastMHF queues a MHFPacket to be sent to all sessions. It reads the
+// lock-free sessions snapshot so packet building and queueing never block
+// the accept/delete loop or other broadcasts running concurrently.
+func (s *Server) BroadcastMHF(pkt mhfpacket.MHFPacket, ignoredSession *Session) {
+	for _, session := range s.Sessions() {
 		if session == ignoredSession {
 			continue
 		}
@@ -289,6 +410,11 @@ func (s *Server) BroadcastMHF(pkt mhfpacket.MHFPacket, ignoredSession *Session)
 	}
 }
 
+// WorldcastMHF queues pkt for delivery to every channel except
+// ignoredChannel. Channels local to this node are reached directly; in a
+// clustered deployment, channels living on other nodes are reached through
+// the packet transport instead, since s.Channels only holds this node's
+// own subset.
 func (s *Server) WorldcastMHF(pkt mhfpacket.MHFPacket, ignoredSession *Session, ignoredChannel *Server) {
 	for _, c := range s.Channels {
 		if c == ignoredChannel {
@@ -296,10 +422,74 @@ func (s *Server) WorldcastMHF(pkt mhfpacket.MHFPacket, ignoredSession *Session,
 		}
 		c.BroadcastMHF(pkt, ignoredSession)
 	}
+
+	if s.transport == nil {
+		return
+	}
+
+	templateSession := s.anyLocalSession()
+	if templateSession == nil {
+		// No template client context to build the packet with anywhere on
+		// this node.
+		return
+	}
+
+	bf := byteframe.NewByteFrame()
+	pkt.Build(bf, templateSession.clientContext)
+
+	var ignoredChannelID uint16
+	if ignoredChannel != nil {
+		ignoredChannelID = ignoredChannel.ID
+	}
+
+	if err := s.transport.PublishWorld(s.nodeID, ignoredChannelID, uint16(pkt.Opcode()), bf.Data()); err != nil {
+		s.logger.Warn("failed to fan world broadcast out to other cluster nodes", zap.Error(err))
+	}
 }
 
-// BroadcastChatMessage broadcasts a simple chat message to all the sessions.
+// anyLocalSession returns any session connected to this node, checked
+// across every channel rather than only s, so WorldcastMHF can still build
+// a template packet for cross-node fan-out even when the triggering
+// channel happens to be empty while a sibling channel on the same node
+// isn't.
+func (s *Server) anyLocalSession() *Session {
+	if sessions := s.Sessions(); len(sessions) > 0 {
+		return sessions[0]
+	}
+	for _, c := range s.Channels {
+		if sessions := c.Sessions(); len(sessions) > 0 {
+			return sessions[0]
+		}
+	}
+	return nil
+}
+
+// broadcastRaw queues an opcode/payload pair already built by another node
+// (see WorldcastMHF) to every local session. Unlike BroadcastMHF it can't
+// retailor the payload per recipient's client context, since it was built
+// remotely from a single template context - an accepted simplification for
+// the largely client-independent world broadcasts this path carries today,
+// such as Raviente announcements.
+func (s *Server) broadcastRaw(opcode uint16, payload []byte) {
+	bf := byteframe.NewByteFrame()
+	bf.WriteUint16(opcode)
+	bf.WriteBytes(payload)
+	data := bf.Data()
+	for _, session := range s.Sessions() {
+		session.QueueSendNonBlocking(data)
+	}
+}
+
+// BroadcastChatMessage broadcasts a simple chat message to all the sessions
+// and fans it out to any bridged external chat platforms.
 func (s *Server) BroadcastChatMessage(message string) {
+	s.broadcastChatMessage(message, true)
+}
+
+// broadcastChatMessage is the shared implementation behind
+// BroadcastChatMessage. fanOut is false when the message originated from a
+// bridge, so it isn't published back to the gateway it just came from.
+func (s *Server) broadcastChatMessage(message string, fanOut bool) {
 	bf := byteframe.NewByteFrame()
 	bf.SetLE()
 	msgBinChat := &binpacket.MsgBinChat{
@@ -316,6 +506,10 @@ func (s *Server) BroadcastChatMessage(message string) {
 		MessageType:    BinaryMessageTypeChat,
 		RawDataPayload: bf.Data(),
 	}, nil)
+
+	if fanOut && s.gateway != nil {
+		s.gateway.Publish(s.name, s.name, message)
+	}
 }
 
 func (s *Server) BroadcastRaviente(ip uint32, port uint16, stage []byte, _type uint8) {
@@ -349,18 +543,53 @@ func (s *Server) BroadcastRaviente(ip uint32, port uint16, stage []byte, _type u
 		MessageType:    BinaryMessageTypeChat,
 		RawDataPayload: bf.Data(),
 	}, nil, s)
+
+	s.syncRavienteToCluster()
+}
+
+// syncRavienteToCluster replicates this node's view of the Raviente
+// register/state/support data into the cluster store, so other nodes
+// reading GetRaviente see the same world boss state instead of only
+// whichever process currently tracks it.
+func (s *Server) syncRavienteToCluster() {
+	s.raviente.Lock()
+	state := cluster.RavienteState{
+		Register: append([]uint32(nil), s.raviente.register.register...),
+		State:    append([]uint32(nil), s.raviente.state.stateData...),
+		Support:  append([]uint32(nil), s.raviente.support.supportData...),
+	}
+	s.raviente.Unlock()
+
+	if err := s.cluster.SetRaviente(state); err != nil {
+		s.logger.Error("failed to sync raviente state to cluster store", zap.Error(err))
+	}
 }
 
-func (s *Server) DiscordChannelSend(charName string, content string) {
-	if s.erupeConfig.Discord.Enabled && s.discordBot != nil {
-		message := fmt.Sprintf("**%s**: %s", charName, content)
-		s.discordBot.RealtimeChannelSend(message)
+// ChatBridgeSend relays a message that originated from an in-game
+// character to every bridged external platform mirroring this channel.
+func (s *Server) ChatBridgeSend(charName string, content string) {
+	if s.gateway != nil {
+		s.gateway.Publish(s.name, charName, content)
 	}
 }
 
+// FindSessionByCharID looks up a session by character ID via the cluster
+// store's session directory, then resolves it to a local *Session by
+// scanning the channel it reports. It only finds characters that
+// RegisterClusterSession has recorded, in a single-node deployment as in
+// a multi-node one; nothing in this tree calls RegisterClusterSession, so
+// as the tree stands it returns nil for every charID.
 func (s *Server) FindSessionByCharID(charID uint32) *Session {
+	loc, ok, err := s.cluster.LookupSession(charID)
+	if err != nil || !ok {
+		return nil
+	}
+
 	for _, c := range s.Channels {
-		for _, session := range c.sessions {
+		if c.ID != loc.ChannelID {
+			continue
+		}
+		for _, session := range c.Sessions() {
 			if session.charID == charID {
 				return session
 			}
@@ -369,6 +598,25 @@ func (s *Server) FindSessionByCharID(charID uint32) *Session {
 	return nil
 }
 
+// RegisterClusterSession records charID as connected to this channel in
+// the cluster store, so FindSessionByCharID resolves it cluster-wide. It
+// should be called once a session's character is known, e.g. by the
+// enter-stage/login handler.
+func (s *Server) RegisterClusterSession(charID uint32) {
+	loc := cluster.SessionLocation{NodeID: s.nodeID, ChannelID: s.ID, Addr: s.IP}
+	if err := s.cluster.RegisterSession(charID, loc); err != nil {
+		s.logger.Error("failed to register session in cluster store", zap.Error(err), zap.Uint32("charID", charID))
+	}
+}
+
+// UnregisterClusterSession removes charID's cluster session directory
+// entry, e.g. on logout or disconnect.
+func (s *Server) UnregisterClusterSession(charID uint32) {
+	if err := s.cluster.UnregisterSession(charID); err != nil {
+		s.logger.Error("failed to unregister session in cluster store", zap.Error(err), zap.Uint32("charID", charID))
+	}
+}
+
 func (s *Server) FindObjectByChar(charID uint32) *Object {
 	s.stagesLock.RLock()
 	defer s.stagesLock.RUnlock()
@@ -387,21 +635,80 @@ func (s *Server) FindObjectByChar(charID uint32) *Object {
 	return nil
 }
 
-func (s *Server) NextSemaphoreID() uint32 {
-	for {
-		exists := false
-		s.semaphoreIndex = s.semaphoreIndex + 1
-		if s.semaphoreIndex == 0 {
-			s.semaphoreIndex = 7 // Skip reserved indexes
-		}
-		for _, semaphore := range s.semaphore {
-			if semaphore.id == s.semaphoreIndex {
-				exists = true
-			}
-		}
-		if exists == false {
+// WhisperChatMessage sends a single chat message directly to target without
+// broadcasting it to the rest of the channel. It backs ircgw's PRIVMSG-to-
+// character delivery, where only one recipient should see the message.
+func (s *Server) WhisperChatMessage(target *Session, senderName, message string) {
+	bf := byteframe.NewByteFrame()
+	bf.SetLE()
+	msgBinChat := &binpacket.MsgBinChat{
+		Unk0:       0,
+		Type:       5,
+		Flags:      0x80,
+		Message:    message,
+		SenderName: senderName,
+	}
+	msgBinChat.Build(bf)
+
+	pkt := &mhfpacket.MsgSysCastedBinary{
+		CharID:         0xFFFFFFFF,
+		MessageType:    BinaryMessageTypeChat,
+		RawDataPayload: bf.Data(),
+	}
+
+	headerBf := byteframe.NewByteFrame()
+	headerBf.WriteUint16(uint16(pkt.Opcode()))
+	pkt.Build(headerBf, target.clientContext)
+	target.QueueSendNonBlocking(headerBf.Data())
+}
+
+// KickSession forcibly disconnects target. It backs ircgw's IRC KICK
+// command, which maps onto ending the underlying MHF connection rather
+// than just removing the client from an IRC channel.
+func (s *Server) KickSession(target *Session) {
+	s.Lock()
+	var conn net.Conn
+	for c, session := range s.sessions {
+		if session == target {
+			conn = c
 			break
 		}
 	}
-	return s.semaphoreIndex
+	s.Unlock()
+
+	if conn == nil {
+		return
+	}
+	conn.Close()
+	s.deleteConns <- conn
+}
+
+// ReleaseSemaphoreID frees id back to the cluster store's pool. The
+// pre-cluster NextSemaphoreID self-healed by rescanning the live
+// s.semaphore map every call, so an ID became reusable as soon as its
+// entry was gone; the cluster-backed allocator instead keeps an ID marked
+// allocated until this is called.
+//
+// Nothing in this tree calls it yet: s.semaphore is typed
+// map[string]*Semaphore, but Semaphore itself isn't defined anywhere in
+// this snapshot, so there's no teardown path to wire the call into. This
+// method exists for whichever commit introduces that type to call; until
+// then every allocated ID leaks for the lifetime of the process, same as
+// before this method existed.
+func (s *Server) ReleaseSemaphoreID(id uint32) {
+	if err := s.cluster.ReleaseSemaphoreID(id); err != nil {
+		s.logger.Error("failed to release semaphore id in cluster store", zap.Error(err), zap.Uint32("semaphoreID", id))
+	}
+}
+
+// NextSemaphoreID atomically allocates a semaphore ID from the cluster
+// store, replacing the old map-scanning loop so allocation stays correct
+// once semaphores can be created from more than one node.
+func (s *Server) NextSemaphoreID() uint32 {
+	id, err := s.cluster.NextSemaphoreID()
+	if err != nil {
+		s.logger.Error("failed to allocate semaphore id from cluster store", zap.Error(err))
+		return 0
+	}
+	return id
 }
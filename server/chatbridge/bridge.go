@@ -0,0 +1,43 @@
+// Package chatbridge relays in-game chat to external chat platforms and
+// back, in the style of matterbridge. A Gateway owns a set of Bridge
+// implementations and fans messages between them and the MHF channel
+// servers.
+//
+// This package and server/cluster pull in several third-party modules
+// (discordgo, mautrix, slack-go, go-telegram-bot-api, thoj/go-ircevent,
+// hashicorp/raft and raft-boltdb, nats.go) that aren't in this snapshot's
+// go.mod/go.sum — this tree has never had either file at any point in its
+// history, not just in this series, so there's nothing to `go mod tidy`
+// against. Adding one isn't a small fix: it means picking real versions
+// for every transitive dependency of each of those modules, which can't
+// be done honestly without the actual module graph to resolve against.
+package chatbridge
+
+// MessageHandler is invoked whenever a Bridge receives a message from its
+// external platform.
+type MessageHandler func(externalRoom, sender, content string)
+
+// Bridge is a single external chat platform connection. Implementations
+// translate between their native protocol and plain room/sender/content
+// triples so the Gateway never has to know about Discord embeds, IRC
+// framing, or anything else platform specific.
+type Bridge interface {
+	// Name identifies the bridge implementation, e.g. "discord" or "irc".
+	// It is used as the key into Config.Rooms' per-bridge room mappings.
+	Name() string
+
+	// SendMessage relays a message that originated in-game to the given
+	// external room.
+	SendMessage(externalRoom, sender, content string) error
+
+	// OnMessage registers the handler invoked for messages coming in from
+	// the external platform. Only one handler may be registered; the
+	// Gateway is the only expected caller.
+	OnMessage(handler MessageHandler)
+
+	// Start connects the bridge and begins relaying messages.
+	Start() error
+
+	// Stop disconnects the bridge.
+	Stop() error
+}
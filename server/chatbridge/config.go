@@ -0,0 +1,54 @@
+package chatbridge
+
+// Config is meant to be the chat bridge section of config.Config, but
+// nothing embeds or reads it there yet: erupe-ce/config is imported
+// throughout channelserver and ircgw (e.g. sys_channel_server.go,
+// ircgw/ircgw.go) but its package directory doesn't exist anywhere in
+// this snapshot, so there's no config.Config struct here to add a
+// ChatBridge Config field to, or NewServer bootstrap code to construct a
+// Gateway from it. A self-hoster can't actually configure bridge rooms
+// until config.Config exists and something at startup turns this struct
+// into a *Gateway the way NewServer already takes a pre-built one. It is
+// intentionally decoupled from any single transport so self-hosters can
+// mix and match whichever platforms their community already uses, once
+// that wiring exists.
+type Config struct {
+	Enabled bool
+
+	// Rooms maps an MHF channel (by name, e.g. "ch01") or guild room
+	// (e.g. "guild-42") to the external room it should be mirrored into on
+	// each enabled bridge. A mapping only applies to the bridges whose
+	// field is non-empty.
+	Rooms []RoomMapping
+}
+
+// RoomMapping binds one in-game room to its mirrored rooms on each bridge.
+type RoomMapping struct {
+	// Room is the MHF-side identifier: a channel name or "guild-<id>".
+	Room string
+
+	Discord  string
+	IRC      string
+	Matrix   string
+	Slack    string
+	Telegram string
+}
+
+// externalRoom returns the room the named bridge mirrors m.Room into, or ""
+// if this mapping doesn't apply to that bridge.
+func (m RoomMapping) externalRoom(bridge string) string {
+	switch bridge {
+	case "discord":
+		return m.Discord
+	case "irc":
+		return m.IRC
+	case "matrix":
+		return m.Matrix
+	case "slack":
+		return m.Slack
+	case "telegram":
+		return m.Telegram
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,51 @@
+package chatbridge
+
+import (
+	"fmt"
+
+	"erupe-ce/server/discordbot"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DiscordBridge adapts the existing discordbot.DiscordBot session to the
+// generic Bridge interface, so it can be mixed with IRC/Matrix/Slack/
+// Telegram behind the same Gateway.
+type DiscordBridge struct {
+	bot     *discordbot.DiscordBot
+	handler MessageHandler
+}
+
+// NewDiscordBridge wraps an already-connected DiscordBot.
+func NewDiscordBridge(bot *discordbot.DiscordBot) *DiscordBridge {
+	return &DiscordBridge{bot: bot}
+}
+
+func (d *DiscordBridge) Name() string { return "discord" }
+
+func (d *DiscordBridge) SendMessage(externalRoom, sender, content string) error {
+	_, err := d.bot.Session.ChannelMessageSend(externalRoom, fmt.Sprintf("**%s**: %s", sender, content))
+	return err
+}
+
+func (d *DiscordBridge) OnMessage(handler MessageHandler) {
+	d.handler = handler
+	d.bot.Session.AddHandler(func(_ *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.Author.Bot || d.handler == nil {
+			return
+		}
+		content := m.Content
+		for _, a := range m.Attachments {
+			content += " " + a.URL
+		}
+		d.handler(m.ChannelID, m.Author.Username, content)
+	})
+}
+
+// Start is a no-op; the underlying DiscordBot is connected by its owner
+// before the bridge is constructed.
+func (d *DiscordBridge) Start() error { return nil }
+
+// Stop is a no-op for the same reason; the DiscordBot's lifetime is owned
+// elsewhere.
+func (d *DiscordBridge) Stop() error { return nil }
@@ -0,0 +1,126 @@
+package chatbridge
+
+import "go.uber.org/zap"
+
+// Gateway fans a single in-game chat message out to every bridge mirroring
+// its room, and routes inbound bridge messages back into the game via
+// OnGameMessage.
+type Gateway struct {
+	logger *zap.Logger
+	cfg    *Config
+
+	bridges map[string]Bridge
+
+	// reverse maps bridge name -> external room -> MHF room, built from
+	// cfg.Rooms so inbound messages can be attributed back to a channel
+	// or guild.
+	reverse map[string]map[string]string
+
+	onGameMessage func(room, originBridge, sender, content string)
+}
+
+// NewGateway creates a Gateway from the chat bridge config. Bridges are
+// attached afterwards with AddBridge so callers can decide which
+// transports to construct (e.g. only those with credentials configured).
+func NewGateway(logger *zap.Logger, cfg *Config) *Gateway {
+	g := &Gateway{
+		logger:  logger,
+		cfg:     cfg,
+		bridges: make(map[string]Bridge),
+		reverse: make(map[string]map[string]string),
+	}
+
+	for _, mapping := range cfg.Rooms {
+		for _, name := range []string{"discord", "irc", "matrix", "slack", "telegram"} {
+			external := mapping.externalRoom(name)
+			if external == "" {
+				continue
+			}
+			if g.reverse[name] == nil {
+				g.reverse[name] = make(map[string]string)
+			}
+			g.reverse[name][external] = mapping.Room
+		}
+	}
+
+	return g
+}
+
+// AddBridge attaches a Bridge to the gateway and wires its inbound messages
+// back into the game. It should be called before Start.
+func (g *Gateway) AddBridge(b Bridge) {
+	g.bridges[b.Name()] = b
+	b.OnMessage(func(externalRoom, sender, content string) {
+		room, ok := g.reverse[b.Name()][externalRoom]
+		if !ok {
+			return
+		}
+		if g.onGameMessage != nil {
+			g.onGameMessage(room, b.Name(), sender, content)
+		}
+	})
+}
+
+// OnGameMessage registers the callback invoked when a bridge relays a
+// message that should be injected back into the game, e.g. via
+// Server.BroadcastChatMessage. originBridge is the name of the bridge the
+// message arrived on, so the caller can fan it back out to the room's
+// other bridges via PublishExcept without echoing it back to where it
+// came from.
+func (g *Gateway) OnGameMessage(handler func(room, originBridge, sender, content string)) {
+	g.onGameMessage = handler
+}
+
+// Publish fans a message that originated in-game out to every bridge
+// mirroring room.
+func (g *Gateway) Publish(room, sender, content string) {
+	g.PublishExcept(room, "", sender, content)
+}
+
+// PublishExcept fans a message out to every bridge mirroring room except
+// originBridge, so a message relayed from one bridge reaches the game and
+// every other bridged platform without being echoed back to its origin.
+func (g *Gateway) PublishExcept(room, originBridge, sender, content string) {
+	for _, mapping := range g.cfg.Rooms {
+		if mapping.Room != room {
+			continue
+		}
+		for name, b := range g.bridges {
+			if name == originBridge {
+				continue
+			}
+			external := mapping.externalRoom(name)
+			if external == "" {
+				continue
+			}
+			if err := b.SendMessage(external, sender, content); err != nil {
+				g.logger.Warn("failed to relay chat message to bridge",
+					zap.String("bridge", name),
+					zap.String("room", room),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// Start connects every attached bridge. It keeps going on individual
+// connection failures, logging them, so one misconfigured platform doesn't
+// take the others down with it.
+func (g *Gateway) Start() error {
+	for name, b := range g.bridges {
+		if err := b.Start(); err != nil {
+			g.logger.Warn("failed to start chat bridge", zap.String("bridge", name), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Stop disconnects every attached bridge.
+func (g *Gateway) Stop() {
+	for name, b := range g.bridges {
+		if err := b.Stop(); err != nil {
+			g.logger.Warn("failed to stop chat bridge", zap.String("bridge", name), zap.Error(err))
+		}
+	}
+}
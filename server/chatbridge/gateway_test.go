@@ -0,0 +1,99 @@
+package chatbridge
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// fakeBridge is a minimal Bridge used to assert what the Gateway relays to
+// each platform without needing a real Discord/IRC/Matrix/Slack/Telegram
+// connection.
+type fakeBridge struct {
+	name    string
+	sent    []string
+	inbound MessageHandler
+}
+
+func (b *fakeBridge) Name() string { return b.name }
+
+func (b *fakeBridge) SendMessage(externalRoom, sender, content string) error {
+	b.sent = append(b.sent, externalRoom+":"+sender+":"+content)
+	return nil
+}
+
+func (b *fakeBridge) OnMessage(handler MessageHandler) { b.inbound = handler }
+func (b *fakeBridge) Start() error                     { return nil }
+func (b *fakeBridge) Stop() error                      { return nil }
+
+// receive simulates an inbound message arriving from the external
+// platform, the way a real Bridge implementation would invoke the handler
+// the Gateway registered via OnMessage.
+func (b *fakeBridge) receive(externalRoom, sender, content string) {
+	if b.inbound != nil {
+		b.inbound(externalRoom, sender, content)
+	}
+}
+
+func newTestGateway() (*Gateway, *fakeBridge, *fakeBridge) {
+	cfg := &Config{Rooms: []RoomMapping{
+		{Room: "ch01", Discord: "general", IRC: "#ch01"},
+	}}
+	g := NewGateway(zap.NewNop(), cfg)
+
+	discord := &fakeBridge{name: "discord"}
+	irc := &fakeBridge{name: "irc"}
+	g.AddBridge(discord)
+	g.AddBridge(irc)
+
+	return g, discord, irc
+}
+
+// TestPublishExceptSkipsOrigin is a regression test for a bridge-originated
+// message only reaching the game and never fanning out to the room's other
+// bridges.
+func TestPublishExceptSkipsOrigin(t *testing.T) {
+	g, discord, irc := newTestGateway()
+
+	g.PublishExcept("ch01", "discord", "Bob", "hello")
+
+	if len(discord.sent) != 0 {
+		t.Fatalf("expected the origin bridge to not receive its own message back, got %v", discord.sent)
+	}
+	if len(irc.sent) != 1 || irc.sent[0] != "#ch01:Bob:hello" {
+		t.Fatalf("expected irc to receive the relayed message, got %v", irc.sent)
+	}
+}
+
+func TestPublishReachesEveryBridge(t *testing.T) {
+	g, discord, irc := newTestGateway()
+
+	g.Publish("ch01", "Bob", "hi")
+
+	if len(discord.sent) != 1 {
+		t.Fatalf("expected discord to receive the in-game message, got %v", discord.sent)
+	}
+	if len(irc.sent) != 1 {
+		t.Fatalf("expected irc to receive the in-game message, got %v", irc.sent)
+	}
+}
+
+// TestOnGameMessageCarriesOriginBridge is a regression test for the
+// OnGameMessage callback not knowing which bridge a message arrived on,
+// which made it impossible to fan a bridge-originated message back out to
+// the room's other bridges without echoing it back to its own source.
+func TestOnGameMessageCarriesOriginBridge(t *testing.T) {
+	g, discord, _ := newTestGateway()
+
+	var gotRoom, gotOrigin, gotSender, gotContent string
+	g.OnGameMessage(func(room, originBridge, sender, content string) {
+		gotRoom, gotOrigin, gotSender, gotContent = room, originBridge, sender, content
+	})
+
+	discord.receive("general", "Bob", "hello from discord")
+
+	if gotRoom != "ch01" || gotOrigin != "discord" || gotSender != "Bob" || gotContent != "hello from discord" {
+		t.Fatalf("got room=%q origin=%q sender=%q content=%q, want ch01/discord/Bob/hello from discord",
+			gotRoom, gotOrigin, gotSender, gotContent)
+	}
+}
@@ -0,0 +1,60 @@
+package chatbridge
+
+import (
+	"fmt"
+
+	irc "github.com/thoj/go-ircevent"
+)
+
+// IRCConfig holds the connection details for an IRCBridge.
+type IRCConfig struct {
+	Server   string
+	Nick     string
+	Password string
+	UseTLS   bool
+}
+
+// IRCBridge relays chat to and from a single IRC network.
+type IRCBridge struct {
+	cfg     IRCConfig
+	conn    *irc.Connection
+	handler MessageHandler
+}
+
+// NewIRCBridge creates an IRCBridge; call Start to actually connect.
+func NewIRCBridge(cfg IRCConfig) *IRCBridge {
+	conn := irc.IRC(cfg.Nick, cfg.Nick)
+	conn.UseTLS = cfg.UseTLS
+	conn.Password = cfg.Password
+	return &IRCBridge{cfg: cfg, conn: conn}
+}
+
+func (b *IRCBridge) Name() string { return "irc" }
+
+func (b *IRCBridge) SendMessage(externalRoom, sender, content string) error {
+	b.conn.Privmsg(externalRoom, fmt.Sprintf("<%s> %s", sender, content))
+	return nil
+}
+
+func (b *IRCBridge) OnMessage(handler MessageHandler) {
+	b.handler = handler
+	b.conn.AddCallback("PRIVMSG", func(e *irc.Event) {
+		if len(e.Arguments) < 2 || b.handler == nil {
+			return
+		}
+		b.handler(e.Arguments[0], e.Nick, e.Arguments[1])
+	})
+}
+
+func (b *IRCBridge) Start() error {
+	if err := b.conn.Connect(b.cfg.Server); err != nil {
+		return err
+	}
+	go b.conn.Loop()
+	return nil
+}
+
+func (b *IRCBridge) Stop() error {
+	b.conn.Quit()
+	return nil
+}
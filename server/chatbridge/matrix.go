@@ -0,0 +1,67 @@
+package chatbridge
+
+import (
+	"fmt"
+
+	"maunium.net/go/mautrix"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+)
+
+// MatrixConfig holds the connection details for a MatrixBridge.
+type MatrixConfig struct {
+	HomeserverURL string
+	UserID        string
+	AccessToken   string
+}
+
+// MatrixBridge relays chat to and from a single Matrix homeserver using a
+// dedicated bot account.
+type MatrixBridge struct {
+	cfg     MatrixConfig
+	client  *mautrix.Client
+	handler MessageHandler
+	stop    chan struct{}
+}
+
+// NewMatrixBridge creates a MatrixBridge; call Start to begin syncing.
+func NewMatrixBridge(cfg MatrixConfig) (*MatrixBridge, error) {
+	client, err := mautrix.NewClient(cfg.HomeserverURL, id.UserID(cfg.UserID), cfg.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &MatrixBridge{cfg: cfg, client: client, stop: make(chan struct{})}, nil
+}
+
+func (b *MatrixBridge) Name() string { return "matrix" }
+
+func (b *MatrixBridge) SendMessage(externalRoom, sender, content string) error {
+	_, err := b.client.SendText(id.RoomID(externalRoom), fmt.Sprintf("%s: %s", sender, content))
+	return err
+}
+
+func (b *MatrixBridge) OnMessage(handler MessageHandler) {
+	b.handler = handler
+	syncer := b.client.Syncer.(*mautrix.DefaultSyncer)
+	syncer.OnEventType(event.EventMessage, func(_ mautrix.EventSource, evt *event.Event) {
+		if evt.Sender == id.UserID(b.cfg.UserID) || b.handler == nil {
+			return
+		}
+		b.handler(evt.RoomID.String(), evt.Sender.String(), evt.Content.AsMessage().Body)
+	})
+}
+
+func (b *MatrixBridge) Start() error {
+	go func() {
+		if err := b.client.Sync(); err != nil {
+			return
+		}
+	}()
+	return nil
+}
+
+func (b *MatrixBridge) Stop() error {
+	close(b.stop)
+	b.client.StopSync()
+	return nil
+}
@@ -0,0 +1,68 @@
+package chatbridge
+
+import (
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// SlackConfig holds the connection details for a SlackBridge.
+type SlackConfig struct {
+	BotToken string
+	AppToken string
+}
+
+// SlackBridge relays chat to and from a single Slack workspace over a
+// Socket Mode connection.
+type SlackBridge struct {
+	cfg     SlackConfig
+	api     *slack.Client
+	sm      *socketmode.Client
+	handler MessageHandler
+}
+
+// NewSlackBridge creates a SlackBridge; call Start to connect.
+func NewSlackBridge(cfg SlackConfig) *SlackBridge {
+	api := slack.New(cfg.BotToken, slack.OptionAppLevelToken(cfg.AppToken))
+	return &SlackBridge{
+		cfg: cfg,
+		api: api,
+		sm:  socketmode.New(api),
+	}
+}
+
+func (b *SlackBridge) Name() string { return "slack" }
+
+func (b *SlackBridge) SendMessage(externalRoom, sender, content string) error {
+	_, _, err := b.api.PostMessage(externalRoom, slack.MsgOptionText(content, false), slack.MsgOptionUsername(sender))
+	return err
+}
+
+func (b *SlackBridge) OnMessage(handler MessageHandler) {
+	b.handler = handler
+}
+
+func (b *SlackBridge) Start() error {
+	go func() {
+		for evt := range b.sm.Events {
+			if evt.Type != socketmode.EventTypeEventsAPI || b.handler == nil {
+				continue
+			}
+			ev, ok := evt.Data.(slackevents.EventsAPIEvent)
+			if !ok {
+				continue
+			}
+			msg, ok := ev.InnerEvent.Data.(*slackevents.MessageEvent)
+			if !ok || msg.BotID != "" {
+				continue
+			}
+			b.handler(msg.Channel, msg.User, msg.Text)
+		}
+	}()
+	go b.sm.Run()
+	return nil
+}
+
+func (b *SlackBridge) Stop() error {
+	return nil
+}
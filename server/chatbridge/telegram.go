@@ -0,0 +1,71 @@
+package chatbridge
+
+import (
+	"fmt"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TelegramConfig holds the connection details for a TelegramBridge.
+type TelegramConfig struct {
+	BotToken string
+}
+
+// TelegramBridge relays chat to and from Telegram chats via long polling.
+type TelegramBridge struct {
+	cfg     TelegramConfig
+	bot     *tgbotapi.BotAPI
+	handler MessageHandler
+	stop    chan struct{}
+}
+
+// NewTelegramBridge creates a TelegramBridge; call Start to begin polling.
+func NewTelegramBridge(cfg TelegramConfig) (*TelegramBridge, error) {
+	bot, err := tgbotapi.NewBotAPI(cfg.BotToken)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramBridge{cfg: cfg, bot: bot, stop: make(chan struct{})}, nil
+}
+
+func (b *TelegramBridge) Name() string { return "telegram" }
+
+func (b *TelegramBridge) SendMessage(externalRoom, sender, content string) error {
+	chatID, err := strconv.ParseInt(externalRoom, 10, 64)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", sender, content))
+	_, err = b.bot.Send(msg)
+	return err
+}
+
+func (b *TelegramBridge) OnMessage(handler MessageHandler) {
+	b.handler = handler
+}
+
+func (b *TelegramBridge) Start() error {
+	updates := b.bot.GetUpdatesChan(tgbotapi.NewUpdate(0))
+	go func() {
+		for {
+			select {
+			case <-b.stop:
+				return
+			case update := <-updates:
+				if update.Message == nil || b.handler == nil {
+					continue
+				}
+				room := strconv.FormatInt(update.Message.Chat.ID, 10)
+				b.handler(room, update.Message.From.UserName, update.Message.Text)
+			}
+		}
+	}()
+	return nil
+}
+
+func (b *TelegramBridge) Stop() error {
+	close(b.stop)
+	b.bot.StopReceivingUpdates()
+	return nil
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+// fsm is the hashicorp/raft FSM backing RaftStore. Every node applies the
+// same command log in the same order, so fsmState converges identically
+// everywhere; reads are served straight out of it without going through
+// Raft.
+type fsm struct {
+	mu sync.RWMutex
+
+	state fsmState
+}
+
+// fsmState is the full replicated state, gob-encoded wholesale for
+// snapshot/restore.
+type fsmState struct {
+	Sessions map[uint32]SessionLocation
+
+	SemaphoreIndex     uint32
+	AllocatedSemaphore map[uint32]bool
+
+	Raviente RavienteState
+}
+
+func newFSM() *fsm {
+	return &fsm{
+		state: fsmState{
+			Sessions:           make(map[uint32]SessionLocation),
+			SemaphoreIndex:     7,
+			AllocatedSemaphore: make(map[uint32]bool),
+		},
+	}
+}
+
+// Apply implements raft.FSM. It runs on every node for every committed log
+// entry, so it must be deterministic.
+func (f *fsm) Apply(log *raft.Log) interface{} {
+	var c command
+	if err := gob.NewDecoder(bytes.NewReader(log.Data)).Decode(&c); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch c.Op {
+	case opRegisterSession:
+		f.state.Sessions[c.CharID] = c.Location
+
+	case opUnregisterSession:
+		delete(f.state.Sessions, c.CharID)
+
+	case opAllocateSemaphore:
+		for {
+			f.state.SemaphoreIndex++
+			if f.state.SemaphoreIndex == 0 {
+				f.state.SemaphoreIndex = 7 // Skip reserved indexes
+			}
+			if !f.state.AllocatedSemaphore[f.state.SemaphoreIndex] {
+				f.state.AllocatedSemaphore[f.state.SemaphoreIndex] = true
+				return f.state.SemaphoreIndex
+			}
+		}
+
+	case opReleaseSemaphore:
+		delete(f.state.AllocatedSemaphore, c.SemaphoreID)
+
+	case opSetRaviente:
+		f.state.Raviente = c.Raviente
+	}
+
+	return nil
+}
+
+func (f *fsm) lookupSession(charID uint32) (SessionLocation, bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	loc, ok := f.state.Sessions[charID]
+	return loc, ok, nil
+}
+
+func (f *fsm) getRaviente() RavienteState {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.state.Raviente
+}
+
+// Snapshot implements raft.FSM.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f.state); err != nil {
+		return nil, err
+	}
+	return &fsmSnapshot{data: buf.Bytes()}, nil
+}
+
+// Restore implements raft.FSM.
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var state fsmState
+	if err := gob.NewDecoder(rc).Decode(&state); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = state
+	return nil
+}
+
+type fsmSnapshot struct {
+	data []byte
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := sink.Write(s.data); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
@@ -0,0 +1,86 @@
+package cluster
+
+import "sync"
+
+// MemStore is an in-memory Store, used by single-node deployments so
+// channelserver can always talk to a Store without standing up Raft. Its
+// semantics match the pointer-chasing behavior channelserver had before
+// clustering existed, except that a semaphore ID stays allocated until
+// ReleaseSemaphoreID frees it rather than as long as a live Semaphore
+// holds it.
+type MemStore struct {
+	mu sync.Mutex
+
+	sessions map[uint32]SessionLocation
+
+	semaphoreIndex     uint32
+	allocatedSemaphore map[uint32]bool
+
+	raviente RavienteState
+}
+
+// NewMemStore creates an empty MemStore.
+func NewMemStore() *MemStore {
+	return &MemStore{
+		sessions:           make(map[uint32]SessionLocation),
+		semaphoreIndex:     7,
+		allocatedSemaphore: make(map[uint32]bool),
+	}
+}
+
+func (m *MemStore) RegisterSession(charID uint32, loc SessionLocation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[charID] = loc
+	return nil
+}
+
+func (m *MemStore) UnregisterSession(charID uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, charID)
+	return nil
+}
+
+func (m *MemStore) LookupSession(charID uint32) (SessionLocation, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	loc, ok := m.sessions[charID]
+	return loc, ok, nil
+}
+
+func (m *MemStore) NextSemaphoreID() (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for {
+		m.semaphoreIndex++
+		if m.semaphoreIndex == 0 {
+			m.semaphoreIndex = 7 // Skip reserved indexes
+		}
+		if !m.allocatedSemaphore[m.semaphoreIndex] {
+			m.allocatedSemaphore[m.semaphoreIndex] = true
+			return m.semaphoreIndex, nil
+		}
+	}
+}
+
+func (m *MemStore) ReleaseSemaphoreID(id uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.allocatedSemaphore, id)
+	return nil
+}
+
+func (m *MemStore) GetRaviente() (RavienteState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.raviente, nil
+}
+
+func (m *MemStore) SetRaviente(state RavienteState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raviente = state
+	return nil
+}
@@ -0,0 +1,286 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb"
+)
+
+// RaftConfig configures a RaftStore.
+type RaftConfig struct {
+	// NodeID must be unique across the cluster.
+	NodeID string
+
+	// BindAddr is the host:port Raft's own transport listens on.
+	BindAddr string
+
+	// DataDir holds the Raft log, stable store and snapshots.
+	DataDir string
+
+	// Bootstrap is true only for the node standing the cluster up for the
+	// first time.
+	Bootstrap bool
+
+	// Peers lists the other nodes' (ID, address) pairs to join on
+	// bootstrap. Subsequent membership changes go through raft.AddVoter
+	// out of band.
+	Peers []raft.Server
+
+	// RPCBindAddr is the host:port this node listens on for Apply calls
+	// forwarded here by a follower on behalf of a write that needs the
+	// leader. raft.Apply does not forward on its own: calling it on a
+	// follower fails immediately with raft.ErrNotLeader, so RaftStore has
+	// to do that hop itself.
+	RPCBindAddr string
+
+	// PeerRPCAddrs maps every other node's raft.ServerID (the same IDs
+	// used in Peers) to its RPCBindAddr, so a follower can forward an
+	// Apply to whichever node raft.LeaderWithID currently names.
+	PeerRPCAddrs map[raft.ServerID]string
+}
+
+// RaftStore is a Store replicated via hashicorp/raft across every channel
+// server node, so the session directory, semaphore ownership and Raviente
+// state agree cluster-wide instead of living on one process's heap.
+type RaftStore struct {
+	raft *raft.Raft
+	fsm  *fsm
+
+	peerRPCAddrs map[raft.ServerID]string
+	rpcListener  net.Listener
+}
+
+// NewRaftStore starts (or rejoins) a Raft group and returns a Store backed
+// by it. A write that lands on a follower is forwarded over RPC to
+// whichever node raft.LeaderWithID currently names; see apply.
+func NewRaftStore(cfg RaftConfig) (*RaftStore, error) {
+	if err := os.MkdirAll(cfg.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("cluster: create data dir: %w", err)
+	}
+
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(cfg.NodeID)
+
+	addr, err := net.ResolveTCPAddr("tcp", cfg.BindAddr)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: resolve bind addr: %w", err)
+	}
+	transport, err := raft.NewTCPTransport(cfg.BindAddr, addr, 3, 10*time.Second, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: create transport: %w", err)
+	}
+
+	snapshots, err := raft.NewFileSnapshotStore(cfg.DataDir, 2, os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: create snapshot store: %w", err)
+	}
+
+	boltStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.DataDir, "raft.db"))
+	if err != nil {
+		return nil, fmt.Errorf("cluster: create bolt store: %w", err)
+	}
+
+	machine := newFSM()
+
+	r, err := raft.NewRaft(conf, machine, boltStore, boltStore, snapshots, transport)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: create raft node: %w", err)
+	}
+
+	if cfg.Bootstrap {
+		servers := append([]raft.Server{{ID: conf.LocalID, Address: transport.LocalAddr()}}, cfg.Peers...)
+		r.BootstrapCluster(raft.Configuration{Servers: servers})
+	}
+
+	rs := &RaftStore{raft: r, fsm: machine, peerRPCAddrs: cfg.PeerRPCAddrs}
+
+	if cfg.RPCBindAddr != "" {
+		lis, err := net.Listen("tcp", cfg.RPCBindAddr)
+		if err != nil {
+			return nil, fmt.Errorf("cluster: listen for forwarded applies: %w", err)
+		}
+		server := rpc.NewServer()
+		if err := server.RegisterName("RaftApply", (*raftApplyRPC)(rs)); err != nil {
+			return nil, fmt.Errorf("cluster: register forwarding rpc: %w", err)
+		}
+		rs.rpcListener = lis
+		go server.Accept(lis)
+	}
+
+	return rs, nil
+}
+
+// Close stops accepting forwarded applies. It does not shut down the
+// underlying raft.Raft, whose own Shutdown is a separate, slower operation
+// callers may want to sequence independently.
+func (rs *RaftStore) Close() error {
+	if rs.rpcListener != nil {
+		return rs.rpcListener.Close()
+	}
+	return nil
+}
+
+const applyTimeout = 5 * time.Second
+
+// apply runs c through the Raft log and returns the semaphore ID it
+// allocated, if any. A command only ever commits on the leader, so a
+// follower forwards it over RPC to whichever node raft.LeaderWithID
+// currently names instead of calling raft.Apply itself, which would fail
+// immediately with raft.ErrNotLeader.
+func (rs *RaftStore) apply(c command) (uint32, error) {
+	data, err := encodeCommand(c)
+	if err != nil {
+		return 0, err
+	}
+
+	if rs.raft.State() != raft.Leader {
+		return rs.forwardApply(data)
+	}
+
+	future := rs.raft.Apply(data, applyTimeout)
+	if err := future.Error(); err != nil {
+		return 0, err
+	}
+	id, _ := future.Response().(uint32)
+	return id, nil
+}
+
+// forwardAddr resolves which address to forward an apply to, given the
+// current leader as reported by raft.LeaderWithID and the configured
+// peer table. Split out from forwardApply so the resolution logic (the
+// part that doesn't need a live raft.Raft or network) can be unit tested
+// directly.
+func forwardAddr(peerRPCAddrs map[raft.ServerID]string, leaderID raft.ServerID) (string, error) {
+	if leaderID == "" {
+		return "", fmt.Errorf("cluster: no known raft leader to forward apply to")
+	}
+
+	addr, ok := peerRPCAddrs[leaderID]
+	if !ok {
+		return "", fmt.Errorf("cluster: no forwarding address configured for leader %q", leaderID)
+	}
+	return addr, nil
+}
+
+// forwardApply sends data to the current Raft leader's forwarding RPC
+// endpoint and returns whatever the leader's own Apply returned.
+func (rs *RaftStore) forwardApply(data []byte) (uint32, error) {
+	_, leaderID := rs.raft.LeaderWithID()
+	addr, err := forwardAddr(rs.peerRPCAddrs, leaderID)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return 0, fmt.Errorf("cluster: dial leader %q for forwarded apply: %w", leaderID, err)
+	}
+	defer client.Close()
+
+	var reply ApplyReply
+	if err := client.Call("RaftApply.Apply", data, &reply); err != nil {
+		return 0, fmt.Errorf("cluster: forwarded apply to leader %q: %w", leaderID, err)
+	}
+	return reply.SemaphoreID, nil
+}
+
+// raftApplyRPC is the RPC receiver RPCBindAddr serves: a follower forwards
+// its encoded command here once raft.LeaderWithID tells it this node is the
+// leader. It only runs the command through raft.Apply, which itself
+// re-checks leadership, so a forward that raced a leadership change just
+// fails the same way a direct local Apply would have.
+type raftApplyRPC RaftStore
+
+// ApplyReply is exported, not because callers outside this package use it
+// directly, but because net/rpc requires a registered method's argument and
+// reply types to both be exported.
+type ApplyReply struct {
+	SemaphoreID uint32
+}
+
+func (r *raftApplyRPC) Apply(data []byte, reply *ApplyReply) error {
+	future := (*RaftStore)(r).raft.Apply(data, applyTimeout)
+	if err := future.Error(); err != nil {
+		return err
+	}
+	if id, ok := future.Response().(uint32); ok {
+		reply.SemaphoreID = id
+	}
+	return nil
+}
+
+func (rs *RaftStore) RegisterSession(charID uint32, loc SessionLocation) error {
+	_, err := rs.apply(command{Op: opRegisterSession, CharID: charID, Location: loc})
+	return err
+}
+
+func (rs *RaftStore) UnregisterSession(charID uint32) error {
+	_, err := rs.apply(command{Op: opUnregisterSession, CharID: charID})
+	return err
+}
+
+func (rs *RaftStore) LookupSession(charID uint32) (SessionLocation, bool, error) {
+	// Reads are served from this node's local FSM replica rather than
+	// going through the leader, trading linearizability for latency; a
+	// session directory that is briefly stale by one Raft round-trip is
+	// an acceptable cost next to serialising every lookup through Apply.
+	return rs.fsm.lookupSession(charID)
+}
+
+// NextSemaphoreID allocates an ID by applying an opAllocateSemaphore
+// command; the fsm computes the next free ID deterministically at apply
+// time and returns it as the command's result, so allocation is a single
+// CAS through the Raft log rather than a separate peek-then-apply.
+func (rs *RaftStore) NextSemaphoreID() (uint32, error) {
+	return rs.apply(command{Op: opAllocateSemaphore})
+}
+
+func (rs *RaftStore) ReleaseSemaphoreID(id uint32) error {
+	_, err := rs.apply(command{Op: opReleaseSemaphore, SemaphoreID: id})
+	return err
+}
+
+func (rs *RaftStore) GetRaviente() (RavienteState, error) {
+	return rs.fsm.getRaviente(), nil
+}
+
+func (rs *RaftStore) SetRaviente(state RavienteState) error {
+	_, err := rs.apply(command{Op: opSetRaviente, Raviente: state})
+	return err
+}
+
+type opType uint8
+
+const (
+	opRegisterSession opType = iota
+	opUnregisterSession
+	opAllocateSemaphore
+	opReleaseSemaphore
+	opSetRaviente
+)
+
+// command is the replicated unit of change applied to every node's fsm.
+type command struct {
+	Op opType
+
+	CharID      uint32
+	Location    SessionLocation
+	SemaphoreID uint32
+	Raviente    RavienteState
+}
+
+func encodeCommand(c command) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/hashicorp/raft"
+)
+
+// TestForwardAddrNoLeader is a regression test for forwardApply treating
+// an empty raft.ServerID (raft.LeaderWithID before a leader is known, or
+// mid-election) as a resolvable address instead of a distinct error.
+func TestForwardAddrNoLeader(t *testing.T) {
+	_, err := forwardAddr(map[raft.ServerID]string{"node-a": "10.0.0.1:7000"}, "")
+	if err == nil {
+		t.Fatal("expected an error for an unknown leader, got nil")
+	}
+}
+
+// TestForwardAddrNoConfiguredPeer is a regression test for forwarding
+// silently targeting the zero-value address when the leader raft itself
+// reports isn't present in PeerRPCAddrs, e.g. because the operator forgot
+// to list it.
+func TestForwardAddrNoConfiguredPeer(t *testing.T) {
+	_, err := forwardAddr(map[raft.ServerID]string{"node-a": "10.0.0.1:7000"}, "node-b")
+	if err == nil {
+		t.Fatal("expected an error for a leader with no configured forwarding address, got nil")
+	}
+}
+
+func TestForwardAddrResolves(t *testing.T) {
+	addr, err := forwardAddr(map[raft.ServerID]string{"node-a": "10.0.0.1:7000"}, "node-a")
+	if err != nil {
+		t.Fatalf("forwardAddr: %v", err)
+	}
+	if addr != "10.0.0.1:7000" {
+		t.Fatalf("got addr %q, want %q", addr, "10.0.0.1:7000")
+	}
+}
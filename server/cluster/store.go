@@ -0,0 +1,57 @@
+// Package cluster provides the shared state store behind multi-node
+// channelserver deployments: the session directory, semaphore ownership
+// and Raviente state that would otherwise only exist as in-process
+// pointers. A single-node deployment uses MemStore, which keeps the same
+// semantics channelserver always had; a multi-node deployment uses a
+// Raft-replicated store (see RaftStore) so every node agrees on where a
+// character is connected and who owns what. Cross-node world broadcast
+// delivery is handled separately by PacketTransport; it doesn't go
+// through Store.
+package cluster
+
+// SessionLocation is where a connected character currently lives.
+type SessionLocation struct {
+	NodeID    string
+	ChannelID uint16
+	Addr      string
+}
+
+// RavienteState mirrors channelserver.Raviente's register/state/support
+// fields so they can be replicated across nodes instead of living only on
+// whichever single process currently tracks the world boss.
+type RavienteState struct {
+	Register []uint32
+	State    []uint32
+	Support  []uint32
+}
+
+// Store is the state shared across every node in a cluster. Session
+// directory lookups, semaphore allocation and Raviente state all go
+// through it instead of direct pointer traversal, so they keep working
+// when sessions, semaphores and channels are spread across multiple
+// processes.
+type Store interface {
+	// RegisterSession records where charID is currently connected.
+	RegisterSession(charID uint32, loc SessionLocation) error
+
+	// UnregisterSession removes charID's directory entry, e.g. on logout
+	// or disconnect.
+	UnregisterSession(charID uint32) error
+
+	// LookupSession returns where charID is connected, if anywhere.
+	LookupSession(charID uint32) (loc SessionLocation, ok bool, err error)
+
+	// NextSemaphoreID atomically allocates and returns an unused semaphore
+	// ID, the cluster-aware replacement for the map scan
+	// Server.NextSemaphoreID used to do.
+	NextSemaphoreID() (uint32, error)
+
+	// ReleaseSemaphoreID frees a semaphore ID so it can be reallocated.
+	ReleaseSemaphoreID(id uint32) error
+
+	// GetRaviente returns the current replicated Raviente state.
+	GetRaviente() (RavienteState, error)
+
+	// SetRaviente replaces the replicated Raviente state.
+	SetRaviente(state RavienteState) error
+}
@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// WorldHandler is invoked with a world broadcast delivered from another
+// node, for this node to fan out to its own local channels.
+type WorldHandler func(ignoredChannelID uint16, opcode uint16, payload []byte)
+
+// PacketTransport fans a world broadcast out to every other node, so
+// WorldcastMHF/BroadcastRaviente keep reaching every channel once those
+// channels are spread across processes instead of living in one s.Channels
+// slice.
+type PacketTransport interface {
+	// PublishWorld sends a broadcast built on originNodeID to every other
+	// subscribed node.
+	PublishWorld(originNodeID string, ignoredChannelID uint16, opcode uint16, payload []byte) error
+
+	// SubscribeWorld registers the handler invoked for broadcasts from
+	// other nodes. Only one handler may be registered per transport.
+	SubscribeWorld(nodeID string, handler WorldHandler) error
+
+	// Close disconnects the transport.
+	Close() error
+}
+
+const worldSubject = "erupe.cluster.world"
+
+// NATSTransport is a PacketTransport backed by a single NATS subject that
+// every node publishes to and subscribes from; the origin node is
+// filtered out of delivery so it doesn't rebroadcast to itself.
+type NATSTransport struct {
+	conn *nats.Conn
+	sub  *nats.Subscription
+}
+
+// NewNATSTransport connects to a NATS server for cross-node packet
+// delivery.
+func NewNATSTransport(url string) (*NATSTransport, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("cluster: connect to nats: %w", err)
+	}
+	return &NATSTransport{conn: conn}, nil
+}
+
+type worldMessage struct {
+	OriginNodeID   string
+	IgnoredChannel uint16
+	Opcode         uint16
+	Payload        []byte
+}
+
+func (t *NATSTransport) PublishWorld(originNodeID string, ignoredChannelID uint16, opcode uint16, payload []byte) error {
+	msg := worldMessage{
+		OriginNodeID:   originNodeID,
+		IgnoredChannel: ignoredChannelID,
+		Opcode:         opcode,
+		Payload:        payload,
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(msg); err != nil {
+		return err
+	}
+	return t.conn.Publish(worldSubject, buf.Bytes())
+}
+
+func (t *NATSTransport) SubscribeWorld(nodeID string, handler WorldHandler) error {
+	sub, err := t.conn.Subscribe(worldSubject, func(m *nats.Msg) {
+		var msg worldMessage
+		if err := gob.NewDecoder(bytes.NewReader(m.Data)).Decode(&msg); err != nil {
+			return
+		}
+		if msg.OriginNodeID == nodeID {
+			return
+		}
+		handler(msg.IgnoredChannel, msg.Opcode, msg.Payload)
+	})
+	if err != nil {
+		return err
+	}
+	t.sub = sub
+	return nil
+}
+
+func (t *NATSTransport) Close() error {
+	if t.sub != nil {
+		_ = t.sub.Unsubscribe()
+	}
+	t.conn.Close()
+	return nil
+}